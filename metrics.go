@@ -0,0 +1,244 @@
+package otel
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/goflash/flash/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// httpDurationBuckets are the standard HTTP-duration-in-seconds histogram
+// bucket boundaries, matching the stable HTTP semconv recommendation.
+var httpDurationBuckets = []float64{.005, .01, .025, .05, .075, .1, .25, .5, .75, 1, 2.5, 5, 7.5, 10}
+
+// unknownRoute is used for the http.route attribute when a request did not
+// match a registered route, to avoid cardinality blowup from raw paths.
+const unknownRoute = "unknown_route"
+
+// MetricsConfig configures the OpenTelemetry metrics middleware.
+// All fields are optional; sensible defaults are used when not provided.
+type MetricsConfig struct {
+	// MeterProvider to use. If nil, otel.GetMeterProvider() is used.
+	MeterProvider metric.MeterProvider
+	// Meter is the instrumentation name passed to MeterProvider.Meter. If
+	// empty, "GoFlash" is used.
+	Meter string
+	// FilterFunc returns true to skip recording metrics for a request (e.g., health checks).
+	FilterFunc func(flash.Ctx) bool
+	// AttributesFunc returns additional attributes to set on every recorded
+	// measurement for a request, analogous to OTelConfig.AttributesFunc.
+	AttributesFunc func(flash.Ctx) []attribute.KeyValue
+}
+
+// metricsState holds the instruments created for a MetricsWithConfig middleware.
+type metricsState struct {
+	duration       metric.Float64Histogram
+	activeRequests metric.Int64UpDownCounter
+	requestSize    metric.Int64Histogram
+	responseSize   metric.Int64Histogram
+}
+
+// Metrics returns middleware that records HTTP server semconv metrics using
+// otel.GetMeterProvider(). Kept for convenience; delegates to
+// MetricsWithConfig.
+func Metrics() flash.Middleware {
+	return MetricsWithConfig(MetricsConfig{})
+}
+
+// MetricsWithConfig returns middleware that records HTTP server semconv
+// metrics using cfg: a request duration histogram, an active requests
+// up-down counter, and request/response body size histograms.
+func MetricsWithConfig(cfg MetricsConfig) flash.Middleware {
+	st := newMetricsState(cfg)
+
+	return func(next flash.Handler) flash.Handler {
+		return func(c flash.Ctx) error {
+			if cfg.FilterFunc != nil && cfg.FilterFunc(c) {
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+
+			baseAttrs := []attribute.KeyValue{
+				attribute.String("http.request.method", c.Method()),
+			}
+
+			activeAttrs := metric.WithAttributes(baseAttrs...)
+			st.activeRequests.Add(ctx, 1, activeAttrs)
+			defer st.activeRequests.Add(ctx, -1, activeAttrs)
+
+			reqSize, haveReqSize := requestContentLength(c.Request())
+
+			origWriter := c.ResponseWriter()
+			counter := &countingResponseWriter{ResponseWriter: origWriter}
+			c.SetResponseWriter(counter)
+			defer c.SetResponseWriter(origWriter)
+
+			start := time.Now()
+			err := next(c)
+			elapsed := time.Since(start)
+
+			status := c.StatusCode()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			route := c.Route()
+			if route == "" {
+				route = unknownRoute
+			}
+
+			attrs := append([]attribute.KeyValue{}, baseAttrs...)
+			attrs = append(attrs,
+				attribute.String("http.route", route),
+				attribute.Int("http.response.status_code", status),
+			)
+			if cfg.AttributesFunc != nil {
+				attrs = append(attrs, cfg.AttributesFunc(c)...)
+			}
+			opt := metric.WithAttributes(attrs...)
+
+			if haveReqSize {
+				st.requestSize.Record(ctx, reqSize, opt)
+			}
+			st.duration.Record(ctx, elapsed.Seconds(), opt)
+			// The counting wrapper observes every byte written, so even a
+			// 0-byte body (e.g. 204 No Content) is a known value worth
+			// recording; only the request-size path needs a presence check,
+			// since Content-Length there can genuinely be absent.
+			st.responseSize.Record(ctx, counter.written, opt)
+
+			return err
+		}
+	}
+}
+
+// InstrumentConfig configures the combined tracing+metrics middleware
+// returned by Instrument.
+type InstrumentConfig struct {
+	// OTel configures the tracing half; see OTelConfig.
+	OTel OTelConfig
+	// Metrics configures the metrics half; see MetricsConfig.
+	Metrics MetricsConfig
+}
+
+// Instrument returns a single flash.Middleware composing both tracing
+// (OTelWithConfig) and metrics (MetricsWithConfig) so callers don't need to
+// wrap a request with both middlewares separately.
+func Instrument(cfg InstrumentConfig) flash.Middleware {
+	tracing := OTelWithConfig(cfg.OTel)
+	metrics := MetricsWithConfig(cfg.Metrics)
+	return func(next flash.Handler) flash.Handler {
+		return tracing(metrics(next))
+	}
+}
+
+func newMetricsState(cfg MetricsConfig) *metricsState {
+	provider := cfg.MeterProvider
+	if provider == nil {
+		provider = otel.GetMeterProvider()
+	}
+	name := cfg.Meter
+	if name == "" {
+		name = "GoFlash"
+	}
+	meter := provider.Meter(name)
+
+	duration, err := meter.Float64Histogram(
+		"http.server.request.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of HTTP server requests."),
+		metric.WithExplicitBucketBoundaries(httpDurationBuckets...),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	activeRequests, err := meter.Int64UpDownCounter(
+		"http.server.active_requests",
+		metric.WithUnit("{request}"),
+		metric.WithDescription("Number of in-flight HTTP server requests."),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	requestSize, err := meter.Int64Histogram(
+		"http.server.request.body.size",
+		metric.WithUnit("By"),
+		metric.WithDescription("Size of HTTP server request bodies."),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	responseSize, err := meter.Int64Histogram(
+		"http.server.response.body.size",
+		metric.WithUnit("By"),
+		metric.WithDescription("Size of HTTP server response bodies."),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return &metricsState{
+		duration:       duration,
+		activeRequests: activeRequests,
+		requestSize:    requestSize,
+		responseSize:   responseSize,
+	}
+}
+
+// requestContentLength returns the request body length in bytes from
+// r.ContentLength, falling back to the Content-Length header when
+// r.ContentLength is -1 (unknown). A request with no body reports 0; it
+// reports false only when neither source gives a valid length.
+func requestContentLength(r *http.Request) (int64, bool) {
+	if r.ContentLength >= 0 {
+		return r.ContentLength, true
+	}
+	h := r.Header.Get("Content-Length")
+	if h == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(h, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// countingResponseWriter wraps an http.ResponseWriter to track the number of
+// response body bytes written, so the response body size can be recorded
+// without relying on an upstream Content-Length header. It forwards
+// http.Flusher and http.Hijacker to the wrapped writer, following the
+// standard net/http wrapping idiom, so streaming (SSE) and upgrade
+// (WebSocket) handlers keep working with this middleware installed.
+type countingResponseWriter struct {
+	http.ResponseWriter
+	written int64
+}
+
+func (w *countingResponseWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.written += int64(n)
+	return n, err
+}
+
+func (w *countingResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *countingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("otel: underlying ResponseWriter does not implement http.Hijacker")
+	}
+	return h.Hijack()
+}
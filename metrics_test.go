@@ -0,0 +1,327 @@
+package otel
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goflash/flash/v2"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func collectMetrics(t *testing.T, reader *metric.ManualReader) metricdata.ResourceMetrics {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	return rm
+}
+
+func findMetric(rm metricdata.ResourceMetrics, name string) (metricdata.Metrics, bool) {
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m, true
+			}
+		}
+	}
+	return metricdata.Metrics{}, false
+}
+
+func TestMetricsWithConfig_RecordsDurationAndRoute(t *testing.T) {
+	reader := metric.NewManualReader()
+	mp := metric.NewMeterProvider(metric.WithReader(reader))
+
+	a := flash.New()
+	a.Use(MetricsWithConfig(MetricsConfig{MeterProvider: mp}))
+	a.GET("/users/:id", func(c flash.Ctx) error { return c.String(http.StatusOK, "ok") })
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	a.ServeHTTP(rec, req)
+
+	rm := collectMetrics(t, reader)
+
+	durMetric, ok := findMetric(rm, "http.server.request.duration")
+	if !ok {
+		t.Fatalf("expected http.server.request.duration metric, got %+v", rm)
+	}
+	hist, ok := durMetric.Data.(metricdata.Histogram[float64])
+	if !ok || len(hist.DataPoints) != 1 {
+		t.Fatalf("expected one histogram data point, got %+v", durMetric.Data)
+	}
+	dp := hist.DataPoints[0]
+	if dp.Count != 1 {
+		t.Fatalf("expected count=1, got %d", dp.Count)
+	}
+
+	var gotRoute, gotStatus bool
+	for _, kv := range dp.Attributes.ToSlice() {
+		if kv.Key == "http.route" && kv.Value.AsString() == "/users/:id" {
+			gotRoute = true
+		}
+		if kv.Key == "http.response.status_code" && kv.Value.AsInt64() == http.StatusOK {
+			gotStatus = true
+		}
+	}
+	if !gotRoute {
+		t.Fatalf("expected http.route=/users/:id attribute, got %v", dp.Attributes.ToSlice())
+	}
+	if !gotStatus {
+		t.Fatalf("expected http.response.status_code=200 attribute, got %v", dp.Attributes.ToSlice())
+	}
+	if got, want := hist.DataPoints[0].Bounds, httpDurationBuckets; !equalFloat64Slices(got, want) {
+		t.Fatalf("bucket boundaries = %v, want %v", got, want)
+	}
+}
+
+func equalFloat64Slices(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMetricsWithConfig_ActiveRequestsReturnsToZero(t *testing.T) {
+	reader := metric.NewManualReader()
+	mp := metric.NewMeterProvider(metric.WithReader(reader))
+
+	a := flash.New()
+	a.Use(MetricsWithConfig(MetricsConfig{MeterProvider: mp}))
+	a.GET("/", func(c flash.Ctx) error { return c.String(http.StatusOK, "ok") })
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	a.ServeHTTP(rec, req)
+
+	rm := collectMetrics(t, reader)
+	m, ok := findMetric(rm, "http.server.active_requests")
+	if !ok {
+		t.Fatalf("expected http.server.active_requests metric")
+	}
+	sum, ok := m.Data.(metricdata.Sum[int64])
+	if !ok || len(sum.DataPoints) != 1 {
+		t.Fatalf("expected one sum data point, got %+v", m.Data)
+	}
+	if got := sum.DataPoints[0].Value; got != 0 {
+		t.Fatalf("expected active requests to return to 0 after request completes, got %d", got)
+	}
+}
+
+func TestMetricsWithConfig_RecordsResponseBodySize(t *testing.T) {
+	reader := metric.NewManualReader()
+	mp := metric.NewMeterProvider(metric.WithReader(reader))
+
+	a := flash.New()
+	a.Use(MetricsWithConfig(MetricsConfig{MeterProvider: mp}))
+	a.GET("/", func(c flash.Ctx) error { return c.String(http.StatusOK, "hello world") })
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	a.ServeHTTP(rec, req)
+
+	rm := collectMetrics(t, reader)
+	m, ok := findMetric(rm, "http.server.response.body.size")
+	if !ok {
+		t.Fatalf("expected http.server.response.body.size metric")
+	}
+	hist := m.Data.(metricdata.Histogram[int64])
+	if len(hist.DataPoints) != 1 {
+		t.Fatalf("expected one histogram data point, got %d", len(hist.DataPoints))
+	}
+	if got, want := hist.DataPoints[0].Sum, int64(len("hello world")); got != want {
+		t.Fatalf("response body size = %d, want %d", got, want)
+	}
+}
+
+func TestMetricsWithConfig_RecordsRequestBodySizeWithRouteAndStatus(t *testing.T) {
+	reader := metric.NewManualReader()
+	mp := metric.NewMeterProvider(metric.WithReader(reader))
+
+	a := flash.New()
+	a.Use(MetricsWithConfig(MetricsConfig{MeterProvider: mp}))
+	a.POST("/users/:id", func(c flash.Ctx) error { return c.Status(http.StatusCreated).String(http.StatusCreated, "ok") })
+
+	rec := httptest.NewRecorder()
+	body := "hello world"
+	req := httptest.NewRequest(http.MethodPost, "/users/1", strings.NewReader(body))
+	req.ContentLength = int64(len(body))
+	a.ServeHTTP(rec, req)
+
+	rm := collectMetrics(t, reader)
+	m, ok := findMetric(rm, "http.server.request.body.size")
+	if !ok {
+		t.Fatalf("expected http.server.request.body.size metric")
+	}
+	hist := m.Data.(metricdata.Histogram[int64])
+	if len(hist.DataPoints) != 1 {
+		t.Fatalf("expected one histogram data point, got %d", len(hist.DataPoints))
+	}
+	dp := hist.DataPoints[0]
+	if got, want := dp.Sum, int64(len(body)); got != want {
+		t.Fatalf("request body size = %d, want %d", got, want)
+	}
+
+	var gotRoute, gotStatus bool
+	for _, kv := range dp.Attributes.ToSlice() {
+		if kv.Key == "http.route" && kv.Value.AsString() == "/users/:id" {
+			gotRoute = true
+		}
+		if kv.Key == "http.response.status_code" && kv.Value.AsInt64() == http.StatusCreated {
+			gotStatus = true
+		}
+	}
+	if !gotRoute {
+		t.Fatalf("expected http.route=/users/:id attribute on request body size, got %v", dp.Attributes.ToSlice())
+	}
+	if !gotStatus {
+		t.Fatalf("expected http.response.status_code=201 attribute on request body size, got %v", dp.Attributes.ToSlice())
+	}
+}
+
+func TestMetricsWithConfig_RecordsZeroResponseBodySize(t *testing.T) {
+	reader := metric.NewManualReader()
+	mp := metric.NewMeterProvider(metric.WithReader(reader))
+
+	a := flash.New()
+	a.Use(MetricsWithConfig(MetricsConfig{MeterProvider: mp}))
+	a.GET("/", func(c flash.Ctx) error {
+		c.ResponseWriter().WriteHeader(http.StatusNoContent)
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	a.ServeHTTP(rec, req)
+
+	rm := collectMetrics(t, reader)
+	m, ok := findMetric(rm, "http.server.response.body.size")
+	if !ok {
+		t.Fatalf("expected http.server.response.body.size metric to be recorded even for a 0-byte body")
+	}
+	hist := m.Data.(metricdata.Histogram[int64])
+	if len(hist.DataPoints) != 1 {
+		t.Fatalf("expected one histogram data point, got %d", len(hist.DataPoints))
+	}
+	if got := hist.DataPoints[0].Sum; got != 0 {
+		t.Fatalf("response body size = %d, want 0", got)
+	}
+	if got := hist.DataPoints[0].Count; got != 1 {
+		t.Fatalf("expected the 0-byte body to count as one observation, got %d", got)
+	}
+}
+
+// fakeFlusherHijacker is a minimal http.ResponseWriter that also implements
+// http.Flusher and http.Hijacker, used to verify that countingResponseWriter
+// forwards both to the wrapped writer.
+type fakeFlusherHijacker struct {
+	http.ResponseWriter
+	flushed   bool
+	hijacked  bool
+	hijackErr error
+}
+
+func (f *fakeFlusherHijacker) Flush() { f.flushed = true }
+
+func (f *fakeFlusherHijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	f.hijacked = true
+	return nil, nil, f.hijackErr
+}
+
+func TestCountingResponseWriter_ForwardsFlusherAndHijacker(t *testing.T) {
+	fake := &fakeFlusherHijacker{ResponseWriter: httptest.NewRecorder()}
+	w := &countingResponseWriter{ResponseWriter: fake}
+
+	flusher, ok := interface{}(w).(http.Flusher)
+	if !ok {
+		t.Fatalf("expected countingResponseWriter to implement http.Flusher")
+	}
+	flusher.Flush()
+	if !fake.flushed {
+		t.Fatalf("expected Flush to be forwarded to the wrapped ResponseWriter")
+	}
+
+	hijacker, ok := interface{}(w).(http.Hijacker)
+	if !ok {
+		t.Fatalf("expected countingResponseWriter to implement http.Hijacker")
+	}
+	if _, _, err := hijacker.Hijack(); err != nil {
+		t.Fatalf("hijack: %v", err)
+	}
+	if !fake.hijacked {
+		t.Fatalf("expected Hijack to be forwarded to the wrapped ResponseWriter")
+	}
+}
+
+func TestCountingResponseWriter_HijackErrorsWhenUnsupported(t *testing.T) {
+	w := &countingResponseWriter{ResponseWriter: httptest.NewRecorder()}
+	if _, _, err := w.Hijack(); err == nil {
+		t.Fatalf("expected an error hijacking a ResponseWriter that doesn't support it")
+	}
+}
+
+func TestMetricsWithConfig_FilterFuncSkips(t *testing.T) {
+	reader := metric.NewManualReader()
+	mp := metric.NewMeterProvider(metric.WithReader(reader))
+
+	a := flash.New()
+	a.Use(MetricsWithConfig(MetricsConfig{
+		MeterProvider: mp,
+		FilterFunc:    func(c flash.Ctx) bool { return c.Path() == "/healthz" },
+	}))
+	a.GET("/healthz", func(c flash.Ctx) error { return c.String(http.StatusOK, "ok") })
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	a.ServeHTTP(rec, req)
+
+	rm := collectMetrics(t, reader)
+	if _, ok := findMetric(rm, "http.server.request.duration"); ok {
+		t.Fatalf("expected no metrics recorded for filtered request")
+	}
+}
+
+func TestInstrument_ComposesTracingAndMetrics(t *testing.T) {
+	reader := metric.NewManualReader()
+	mp := metric.NewMeterProvider(metric.WithReader(reader))
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	a := flash.New()
+	a.Use(Instrument(InstrumentConfig{
+		OTel:    OTelConfig{Tracer: tp.Tracer("test")},
+		Metrics: MetricsConfig{MeterProvider: mp},
+	}))
+	a.GET("/", func(c flash.Ctx) error {
+		if !Span(c).IsRecording() {
+			t.Fatalf("expected an active recording span from the tracing half")
+		}
+		return c.String(http.StatusOK, "ok")
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	a.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("code=%d", rec.Code)
+	}
+
+	rm := collectMetrics(t, reader)
+	if _, ok := findMetric(rm, "http.server.request.duration"); !ok {
+		t.Fatalf("expected metrics to be recorded via Instrument")
+	}
+}
@@ -2,7 +2,11 @@
 package otel
 
 import (
+	"net"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/goflash/flash/v2"
@@ -13,6 +17,122 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+// SemConv selects which set of HTTP attribute keys OTelWithConfig emits.
+type SemConv int
+
+const (
+	// SemConvStable emits the stable 1.x HTTP semantic conventions
+	// (http.request.method, url.path, url.scheme, http.response.status_code,
+	// network.peer.address, network.peer.port, user_agent.original,
+	// server.address, server.port, http.route). This is the default.
+	SemConvStable SemConv = iota
+	// SemConvLegacy emits the attribute keys this middleware used prior to
+	// the stable conventions (http.method, http.target, http.status_code,
+	// net.peer.addr).
+	SemConvLegacy
+	// SemConvDup emits both the legacy and stable keys side by side, to ease
+	// migrating dashboards and alerts off the legacy names.
+	SemConvDup
+)
+
+// resolveSemConv determines the effective SemConv mode, honoring the
+// OTEL_SEMCONV_STABILITY_OPT_IN environment variable (as used by upstream Go
+// contrib instrumentations) ahead of cfg, so existing deployments can pin
+// behavior without a code change.
+func resolveSemConv(cfg SemConv) SemConv {
+	switch os.Getenv("OTEL_SEMCONV_STABILITY_OPT_IN") {
+	case "http/dup":
+		return SemConvDup
+	case "http":
+		return SemConvStable
+	default:
+		return cfg
+	}
+}
+
+// canonicalHTTPMethods is the uppercase method set recognized by the stable
+// HTTP semantic conventions; anything else normalizes to "_OTHER".
+var canonicalHTTPMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodConnect: true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// normalizeMethod maps m onto the canonical uppercase method set used by the
+// stable HTTP semantic conventions, reporting whether m had to be normalized
+// to "_OTHER" so the caller can also record the original value.
+func normalizeMethod(m string) (canonical string, changed bool) {
+	upper := strings.ToUpper(m)
+	if canonicalHTTPMethods[upper] {
+		return upper, false
+	}
+	return "_OTHER", true
+}
+
+// httpAttrs builds the request/response attributes for mode: the legacy
+// keys, the stable keys, or both (SemConvDup).
+func httpAttrs(mode SemConv, c flash.Ctx, r *http.Request, status int) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+
+	if mode == SemConvLegacy || mode == SemConvDup {
+		attrs = append(attrs,
+			attribute.String("http.method", c.Method()),
+			attribute.String("http.target", c.Path()),
+			attribute.String("net.peer.addr", r.RemoteAddr),
+			attribute.Int("http.status_code", status),
+		)
+	}
+
+	if mode == SemConvStable || mode == SemConvDup {
+		canonical, changed := normalizeMethod(c.Method())
+		attrs = append(attrs, attribute.String("http.request.method", canonical))
+		if changed {
+			attrs = append(attrs, attribute.String("http.request.method_original", c.Method()))
+		}
+
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		attrs = append(attrs,
+			attribute.String("url.path", c.Path()),
+			attribute.String("url.scheme", scheme),
+		)
+
+		if host, port, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			attrs = append(attrs, attribute.String("network.peer.address", host))
+			if p, err := strconv.Atoi(port); err == nil {
+				attrs = append(attrs, attribute.Int("network.peer.port", p))
+			}
+		} else if r.RemoteAddr != "" {
+			attrs = append(attrs, attribute.String("network.peer.address", r.RemoteAddr))
+		}
+
+		if ua := r.UserAgent(); ua != "" {
+			attrs = append(attrs, attribute.String("user_agent.original", ua))
+		}
+
+		if host, port, err := net.SplitHostPort(r.Host); err == nil {
+			attrs = append(attrs, attribute.String("server.address", host))
+			if p, err := strconv.Atoi(port); err == nil {
+				attrs = append(attrs, attribute.Int("server.port", p))
+			}
+		} else if r.Host != "" {
+			attrs = append(attrs, attribute.String("server.address", r.Host))
+		}
+
+		attrs = append(attrs, attribute.Int("http.response.status_code", status))
+	}
+
+	return attrs
+}
+
 // OTelConfig configures the OpenTelemetry middleware.
 // All fields are optional; sensible defaults are used when not provided.
 type OTelConfig struct {
@@ -37,6 +157,55 @@ type OTelConfig struct {
 	ServiceName string
 	// ExtraAttributes are appended to span attributes.
 	ExtraAttributes []attribute.KeyValue
+	// PublicEndpoint, if true, marks this route as Internet-facing. The incoming
+	// trace context is still extracted via cfg.Propagator, but it is attached to
+	// the new server span as a Link rather than as its parent, so an arbitrary
+	// caller cannot inject itself as the parent of internal traces. Analogous to
+	// otelmux's WithPublicEndpoint.
+	PublicEndpoint bool
+	// PublicEndpointFn reports whether a given request should be treated as a
+	// public endpoint (see PublicEndpoint). When set, it takes precedence over
+	// PublicEndpoint.
+	PublicEndpointFn func(flash.Ctx) bool
+	// CapturedRequestHeaders lists request header names (case-insensitive) to
+	// capture as span attributes "http.request.header.<lowercased-name>",
+	// mirroring Traefik's captured headers feature. Headers not present on the
+	// request are omitted rather than emitted as empty.
+	CapturedRequestHeaders []string
+	// CapturedResponseHeaders is like CapturedRequestHeaders but for response
+	// headers, captured after the handler returns and emitted as
+	// "http.response.header.<lowercased-name>".
+	CapturedResponseHeaders []string
+	// HeaderValueSanitizer optionally rewrites captured header values before
+	// they are attached to the span, e.g. to redact Authorization. It receives
+	// the header name as passed in Captured*Headers and its values.
+	HeaderValueSanitizer func(name string, values []string) []string
+	// SemConv selects which set of HTTP attribute keys are emitted: the
+	// legacy keys, the stable 1.x keys (the default), or both. The
+	// OTEL_SEMCONV_STABILITY_OPT_IN environment variable, when set to "http"
+	// or "http/dup", takes precedence over this field.
+	SemConv SemConv
+}
+
+// capturedHeaderAttrs builds span attributes for the header names in names that
+// are present in header, using attrPrefix (e.g. "http.request.header.") and
+// applying sanitize if non-nil.
+func capturedHeaderAttrs(attrPrefix string, header http.Header, names []string, sanitize func(name string, values []string) []string) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	for _, name := range names {
+		values := header.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+		if sanitize != nil {
+			values = sanitize(name, values)
+		}
+		if len(values) == 0 {
+			continue
+		}
+		attrs = append(attrs, attribute.StringSlice(attrPrefix+strings.ToLower(name), values))
+	}
+	return attrs
 }
 
 // OTel returns middleware that creates an OpenTelemetry server span for each request.
@@ -55,6 +224,7 @@ func OTelWithConfig(cfg OTelConfig) flash.Middleware {
 	if prop == nil {
 		prop = otel.GetTextMapPropagator()
 	}
+	semConv := resolveSemConv(cfg.SemConv)
 
 	defaultSpanName := func(c flash.Ctx) string {
 		name := c.Method() + " " + c.Path()
@@ -89,8 +259,31 @@ func OTelWithConfig(cfg OTelConfig) flash.Middleware {
 			}
 
 			// Extract remote context and start a server span
-			reqCtx := prop.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
-			reqCtx, span := tracer.Start(reqCtx, name, trace.WithSpanKind(trace.SpanKindServer))
+			extractedCtx := prop.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			startOpts := []trace.SpanStartOption{trace.WithSpanKind(trace.SpanKindServer)}
+			reqCtx := extractedCtx
+
+			public := cfg.PublicEndpoint
+			if cfg.PublicEndpointFn != nil {
+				public = cfg.PublicEndpointFn(c)
+			}
+			if public {
+				// Don't let an arbitrary caller become the parent of internal
+				// traces; keep the upstream relationship as a link instead.
+				// trace.WithNewRoot() strips the span parent below, so reqCtx
+				// stays the extracted context and other propagated values
+				// (e.g. Baggage) are preserved.
+				startOpts = append(startOpts, trace.WithNewRoot())
+				if sc := trace.SpanContextFromContext(extractedCtx); sc.IsValid() {
+					startOpts = append(startOpts, trace.WithLinks(trace.Link{
+						SpanContext: sc,
+						Attributes:  []attribute.KeyValue{attribute.Bool("public_endpoint.link", true)},
+					}))
+				}
+			}
+
+			reqCtx, span := tracer.Start(reqCtx, name, startOpts...)
 			defer span.End()
 
 			// propagate context into request for downstream calls
@@ -107,11 +300,7 @@ func OTelWithConfig(cfg OTelConfig) flash.Middleware {
 			}
 
 			// Base attributes (computed late to include route if set post-match)
-			attrs := []attribute.KeyValue{
-				attribute.String("http.method", c.Method()),
-				attribute.String("http.target", c.Path()),
-				attribute.String("net.peer.addr", r.RemoteAddr),
-			}
+			attrs := httpAttrs(semConv, c, r, status)
 			if rt := c.Route(); rt != "" {
 				attrs = append(attrs, attribute.String("http.route", rt))
 			}
@@ -124,7 +313,12 @@ func OTelWithConfig(cfg OTelConfig) flash.Middleware {
 			if len(cfg.ExtraAttributes) > 0 {
 				attrs = append(attrs, cfg.ExtraAttributes...)
 			}
-			attrs = append(attrs, attribute.Int("http.status_code", status))
+			if len(cfg.CapturedRequestHeaders) > 0 {
+				attrs = append(attrs, capturedHeaderAttrs("http.request.header.", r.Header, cfg.CapturedRequestHeaders, cfg.HeaderValueSanitizer)...)
+			}
+			if len(cfg.CapturedResponseHeaders) > 0 {
+				attrs = append(attrs, capturedHeaderAttrs("http.response.header.", c.ResponseWriter().Header(), cfg.CapturedResponseHeaders, cfg.HeaderValueSanitizer)...)
+			}
 			if cfg.RecordDuration {
 				attrs = append(attrs, attribute.Float64("http.server.duration_ms", float64(elapsed)/float64(time.Millisecond)))
 			}
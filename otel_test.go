@@ -10,6 +10,7 @@ import (
 	"github.com/goflash/flash/v2"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/propagation"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
@@ -155,6 +156,304 @@ func TestHelpers_NoActiveSpan_NoPanicAndNotRecording(t *testing.T) {
 	}
 }
 
+func TestOTelWithConfig_PublicEndpoint_LinksInsteadOfParenting(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	a := flash.New()
+	a.Use(OTelWithConfig(OTelConfig{
+		Tracer:         tp.Tracer("test"),
+		Propagator:     propagation.TraceContext{},
+		PublicEndpoint: true,
+	}))
+	a.GET("/", func(c flash.Ctx) error { return c.String(http.StatusOK, "ok") })
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	// Non-zero trace and span IDs make the extracted span context valid; 01 is the sampled flag.
+	req.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	a.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("code=%d", rec.Code)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	got := spans[0]
+
+	if got.Parent().IsRemote() {
+		t.Fatalf("expected span to not be parented by the remote context")
+	}
+	if got.Parent().IsValid() {
+		t.Fatalf("expected span to have no valid parent (new root)")
+	}
+
+	links := got.Links()
+	if len(links) != 1 {
+		t.Fatalf("expected exactly one link, got %d", len(links))
+	}
+	if got, want := links[0].SpanContext.TraceID().String(), "0af7651916cd43dd8448eb211c80319c"; got != want {
+		t.Fatalf("link trace id = %s, want %s", got, want)
+	}
+	if got, want := links[0].SpanContext.SpanID().String(), "b7ad6b7169203331"; got != want {
+		t.Fatalf("link span id = %s, want %s", got, want)
+	}
+}
+
+func TestOTelWithConfig_PublicEndpoint_PreservesBaggage(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	a := flash.New()
+	a.Use(OTelWithConfig(OTelConfig{
+		Tracer:         tp.Tracer("test"),
+		Propagator:     propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+		PublicEndpoint: true,
+	}))
+
+	var gotMember string
+	a.GET("/", func(c flash.Ctx) error {
+		gotMember = baggage.FromContext(c.Request().Context()).Member("userid").Value()
+		return c.String(http.StatusOK, "ok")
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	req.Header.Set("baggage", "userid=alice")
+	a.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("code=%d", rec.Code)
+	}
+
+	if gotMember != "alice" {
+		t.Fatalf("expected baggage member userid=alice to survive public-endpoint handling, got %q", gotMember)
+	}
+}
+
+func TestOTelWithConfig_PublicEndpointFn_TakesPrecedence(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	a := flash.New()
+	a.Use(OTelWithConfig(OTelConfig{
+		Tracer:         tp.Tracer("test"),
+		Propagator:     propagation.TraceContext{},
+		PublicEndpoint: false,
+		PublicEndpointFn: func(c flash.Ctx) bool {
+			return c.Path() == "/public"
+		},
+	}))
+	a.GET("/public", func(c flash.Ctx) error { return c.String(http.StatusOK, "ok") })
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/public", nil)
+	req.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	a.ServeHTTP(rec, req)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Parent().IsValid() {
+		t.Fatalf("expected PublicEndpointFn to take precedence and produce a new root span")
+	}
+	if len(spans[0].Links()) != 1 {
+		t.Fatalf("expected exactly one link, got %d", len(spans[0].Links()))
+	}
+}
+
+func TestOTelWithConfig_CapturedHeaders(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	a := flash.New()
+	a.Use(OTelWithConfig(OTelConfig{
+		Tracer:                  tp.Tracer("test"),
+		CapturedRequestHeaders:  []string{"X-Request-Id", "X-Missing"},
+		CapturedResponseHeaders: []string{"X-Served-By"},
+		HeaderValueSanitizer: func(name string, values []string) []string {
+			if name == "X-Request-Id" {
+				return []string{"REDACTED"}
+			}
+			return values
+		},
+	}))
+	a.GET("/", func(c flash.Ctx) error {
+		c.Header("X-Served-By", "svc-1")
+		return c.String(http.StatusOK, "ok")
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Add("X-Request-Id", "abc")
+	req.Header.Add("x-request-id", "def") // case-insensitive, same header
+	a.ServeHTTP(rec, req)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	attrs := spans[0].Attributes()
+
+	var gotReq, gotResp, gotMissing bool
+	for _, kv := range attrs {
+		switch kv.Key {
+		case "http.request.header.x-request-id":
+			gotReq = true
+			if got, want := kv.Value.AsStringSlice(), []string{"REDACTED"}; len(got) != 1 || got[0] != want[0] {
+				t.Fatalf("sanitized request header = %v, want %v", got, want)
+			}
+		case "http.request.header.x-missing":
+			gotMissing = true
+		case "http.response.header.x-served-by":
+			gotResp = true
+			if got, want := kv.Value.AsStringSlice(), []string{"svc-1"}; len(got) != 1 || got[0] != want[0] {
+				t.Fatalf("response header = %v, want %v", got, want)
+			}
+		}
+	}
+	if !gotReq {
+		t.Fatalf("expected http.request.header.x-request-id attribute, attrs=%v", attrs)
+	}
+	if !gotResp {
+		t.Fatalf("expected http.response.header.x-served-by attribute, attrs=%v", attrs)
+	}
+	if gotMissing {
+		t.Fatalf("expected missing header to be omitted, attrs=%v", attrs)
+	}
+}
+
+func attrMap(attrs []attribute.KeyValue) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value, len(attrs))
+	for _, kv := range attrs {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestOTelWithConfig_SemConvDefaultIsStable(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	a := flash.New()
+	a.Use(OTelWithConfig(OTelConfig{Tracer: tp.Tracer("test")}))
+	a.GET("/items/:id", func(c flash.Ctx) error { return c.String(http.StatusOK, "ok") })
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+	req.Header.Set("User-Agent", "test-agent")
+	a.ServeHTTP(rec, req)
+
+	attrs := attrMap(recorder.Ended()[0].Attributes())
+	if v, ok := attrs["http.request.method"]; !ok || v.AsString() != "GET" {
+		t.Fatalf("http.request.method = %v", attrs["http.request.method"])
+	}
+	if v, ok := attrs["url.path"]; !ok || v.AsString() != "/items/1" {
+		t.Fatalf("url.path = %v", attrs["url.path"])
+	}
+	if v, ok := attrs["http.response.status_code"]; !ok || v.AsInt64() != http.StatusOK {
+		t.Fatalf("http.response.status_code = %v", attrs["http.response.status_code"])
+	}
+	if v, ok := attrs["user_agent.original"]; !ok || v.AsString() != "test-agent" {
+		t.Fatalf("user_agent.original = %v", attrs["user_agent.original"])
+	}
+	if _, ok := attrs["http.method"]; ok {
+		t.Fatalf("did not expect legacy http.method attribute in default stable mode")
+	}
+}
+
+func TestOTelWithConfig_SemConvLegacy(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	a := flash.New()
+	a.Use(OTelWithConfig(OTelConfig{Tracer: tp.Tracer("test"), SemConv: SemConvLegacy}))
+	a.GET("/", func(c flash.Ctx) error { return c.String(http.StatusOK, "ok") })
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	a.ServeHTTP(rec, req)
+
+	attrs := attrMap(recorder.Ended()[0].Attributes())
+	if v, ok := attrs["http.method"]; !ok || v.AsString() != "GET" {
+		t.Fatalf("http.method = %v", attrs["http.method"])
+	}
+	if v, ok := attrs["http.status_code"]; !ok || v.AsInt64() != http.StatusOK {
+		t.Fatalf("http.status_code = %v", attrs["http.status_code"])
+	}
+	if _, ok := attrs["http.request.method"]; ok {
+		t.Fatalf("did not expect stable http.request.method attribute in legacy mode")
+	}
+}
+
+func TestOTelWithConfig_SemConvDupEmitsBoth(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	a := flash.New()
+	a.Use(OTelWithConfig(OTelConfig{Tracer: tp.Tracer("test"), SemConv: SemConvDup}))
+	a.GET("/", func(c flash.Ctx) error { return c.String(http.StatusOK, "ok") })
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	a.ServeHTTP(rec, req)
+
+	attrs := attrMap(recorder.Ended()[0].Attributes())
+	if _, ok := attrs["http.method"]; !ok {
+		t.Fatalf("expected legacy http.method attribute in dup mode")
+	}
+	if _, ok := attrs["http.request.method"]; !ok {
+		t.Fatalf("expected stable http.request.method attribute in dup mode")
+	}
+}
+
+func TestOTelWithConfig_SemConvMethodOther(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	a := flash.New()
+	a.Use(OTelWithConfig(OTelConfig{Tracer: tp.Tracer("test")}))
+	a.Handle("REPORT", "/", func(c flash.Ctx) error { return c.String(http.StatusOK, "ok") })
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("REPORT", "/", nil)
+	a.ServeHTTP(rec, req)
+
+	attrs := attrMap(recorder.Ended()[0].Attributes())
+	if v, ok := attrs["http.request.method"]; !ok || v.AsString() != "_OTHER" {
+		t.Fatalf("http.request.method = %v, want _OTHER", attrs["http.request.method"])
+	}
+	if v, ok := attrs["http.request.method_original"]; !ok || v.AsString() != "REPORT" {
+		t.Fatalf("http.request.method_original = %v, want REPORT", attrs["http.request.method_original"])
+	}
+}
+
+func TestOTelWithConfig_SemConvEnvVarOverridesField(t *testing.T) {
+	t.Setenv("OTEL_SEMCONV_STABILITY_OPT_IN", "http/dup")
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	a := flash.New()
+	a.Use(OTelWithConfig(OTelConfig{Tracer: tp.Tracer("test"), SemConv: SemConvLegacy}))
+	a.GET("/", func(c flash.Ctx) error { return c.String(http.StatusOK, "ok") })
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	a.ServeHTTP(rec, req)
+
+	attrs := attrMap(recorder.Ended()[0].Attributes())
+	if _, ok := attrs["http.method"]; !ok {
+		t.Fatalf("expected legacy http.method attribute when env var requests dup mode")
+	}
+	if _, ok := attrs["http.request.method"]; !ok {
+		t.Fatalf("expected stable http.request.method attribute when env var overrides SemConvLegacy field")
+	}
+}
+
 func TestHelpers_WithActiveSpan_AttributesAndEventsCaptured(t *testing.T) {
 	// Setup tracer provider with a span recorder
 	recorder := tracetest.NewSpanRecorder()
@@ -0,0 +1,184 @@
+package otel
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TransportConfig configures the traced http.RoundTripper returned by
+// Transport. All fields are optional; sensible defaults are used when not
+// provided.
+type TransportConfig struct {
+	// TracerProvider supplies the Tracer used to start client spans. If nil,
+	// otel.GetTracerProvider() is used.
+	TracerProvider trace.TracerProvider
+	// Propagator injects the active span into outgoing request headers. If
+	// nil, otel.GetTextMapPropagator() is used.
+	Propagator propagation.TextMapPropagator
+	// SpanNameFunc formats the span name. If nil, defaults to "HTTP METHOD".
+	SpanNameFunc func(*http.Request) string
+	// URLSanitizer rewrites the request URL before it is recorded as the
+	// url.full attribute, e.g. to strip query parameters or userinfo. If nil,
+	// the URL is recorded with its userinfo stripped and query kept.
+	URLSanitizer func(*url.URL) string
+}
+
+// TransportOption configures a TransportConfig.
+type TransportOption func(*TransportConfig)
+
+// WithTracerProvider sets the TracerProvider used to start client spans.
+func WithTracerProvider(tp trace.TracerProvider) TransportOption {
+	return func(c *TransportConfig) { c.TracerProvider = tp }
+}
+
+// WithPropagator sets the propagator used to inject the active span into
+// outgoing request headers.
+func WithPropagator(p propagation.TextMapPropagator) TransportOption {
+	return func(c *TransportConfig) { c.Propagator = p }
+}
+
+// WithSpanNameFunc sets the function used to format the client span name.
+func WithSpanNameFunc(f func(*http.Request) string) TransportOption {
+	return func(c *TransportConfig) { c.SpanNameFunc = f }
+}
+
+// WithURLSanitizer sets the function used to render the url.full attribute
+// from the outgoing request's URL, e.g. to strip query parameters or
+// credentials before they reach a backend.
+func WithURLSanitizer(f func(*url.URL) string) TransportOption {
+	return func(c *TransportConfig) { c.URLSanitizer = f }
+}
+
+// defaultURLSanitizer strips userinfo (credentials) from the URL but keeps
+// the query string, matching net/http's own String() rendering minus creds.
+func defaultURLSanitizer(u *url.URL) string {
+	if u.User == nil {
+		return u.String()
+	}
+	stripped := *u
+	stripped.User = nil
+	return stripped.String()
+}
+
+// otelTransport is an http.RoundTripper that wraps base with client-kind
+// OpenTelemetry spans, analogous to the unitel project's traced transport.
+type otelTransport struct {
+	base   http.RoundTripper
+	tracer trace.Tracer
+	prop   propagation.TextMapPropagator
+	cfg    TransportConfig
+}
+
+// Transport wraps base with an http.RoundTripper that starts a client-kind
+// span for every outgoing request, injects the active trace context into its
+// headers, and records semconv attributes. If base is nil,
+// http.DefaultTransport is used.
+func Transport(base http.RoundTripper, opts ...TransportOption) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	var cfg TransportConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	tp := cfg.TracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	prop := cfg.Propagator
+	if prop == nil {
+		prop = otel.GetTextMapPropagator()
+	}
+
+	return &otelTransport{
+		base:   base,
+		tracer: tp.Tracer("github.com/goflash/otel/v2"),
+		prop:   prop,
+		cfg:    cfg,
+	}
+}
+
+// NewClient returns an *http.Client whose Transport is wrapped with Transport
+// using opts.
+func NewClient(opts ...TransportOption) *http.Client {
+	return &http.Client{Transport: Transport(nil, opts...)}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *otelTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	name := "HTTP " + req.Method
+	if t.cfg.SpanNameFunc != nil {
+		if n := t.cfg.SpanNameFunc(req); n != "" {
+			name = n
+		}
+	}
+
+	sanitizer := t.cfg.URLSanitizer
+	if sanitizer == nil {
+		sanitizer = defaultURLSanitizer
+	}
+
+	ctx, span := t.tracer.Start(req.Context(), name, trace.WithSpanKind(trace.SpanKindClient))
+
+	attrs := []attribute.KeyValue{
+		attribute.String("http.request.method", req.Method),
+		attribute.String("url.full", sanitizer(req.URL)),
+	}
+	if host := req.URL.Hostname(); host != "" {
+		attrs = append(attrs, attribute.String("server.address", host))
+	}
+	if port := req.URL.Port(); port != "" {
+		if p, err := strconv.Atoi(port); err == nil {
+			attrs = append(attrs, attribute.Int("server.port", p))
+		}
+	}
+	span.SetAttributes(attrs...)
+
+	req = req.WithContext(ctx)
+	t.prop.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.End()
+		return resp, err
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= 500 {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+
+	// Spans must end after the body is fully read for accurate timing, so
+	// delay End() until the caller closes the response body.
+	var endOnce sync.Once
+	resp.Body = &endOnClose{ReadCloser: resp.Body, end: func() { endOnce.Do(func() { span.End() }) }}
+
+	return resp, nil
+}
+
+// endOnClose wraps a response body so that Close ends the associated span
+// exactly once, regardless of how many times Close is called.
+type endOnClose struct {
+	io.ReadCloser
+	end func()
+}
+
+func (c *endOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.end()
+	return err
+}